@@ -0,0 +1,166 @@
+package httpsched
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mesos/mesos-go/api/v1/lib/httpcli"
+	"github.com/mesos/mesos-go/api/v1/lib/scheduler/calls"
+)
+
+// errNoEndpoints is returned by NewClusterCaller when invoked with an empty endpoint list.
+var errNoEndpoints = errors.New("httpsched: at least one endpoint is required")
+
+// NewClusterCaller is like NewCaller except that it accepts a set of candidate master endpoints
+// instead of a single, pre-bound httpcli.Client. The returned Caller pins one of the given
+// endpoints (initially chosen at random, similar to etcd's client.Client endpoint pinning) and
+// transparently rotates through the remainder when the pinned endpoint becomes unreachable --
+// see ClusterEndpoints for details. This removes the operational requirement that a framework
+// always be bootstrapped with the current Mesos leader's URL.
+func NewClusterCaller(endpoints []string, opts ...Option) (calls.Caller, error) {
+	if len(endpoints) == 0 {
+		return nil, errNoEndpoints
+	}
+	el := newEndpointList(endpoints)
+	cl := httpcli.New(httpcli.Endpoint(el.Pinned()))
+	allOpts := make([]Option, 0, len(opts)+1)
+	allOpts = append(allOpts, setEndpointList(el))
+	allOpts = append(allOpts, opts...)
+	return NewCaller(cl, allOpts...), nil
+}
+
+// ClusterEndpoints configures a client, created via NewCaller, with a set of candidate master
+// endpoints. Without this option a client only ever talks to the single endpoint it was bound to
+// plus whatever leader it is redirected to via a Mesos 307; with it, connection refused, timeout,
+// DNS failure, and 5xx responses from the pinned endpoint also trigger a rotation to the next
+// candidate, under the same RedirectSettings backoff schedule. Prefer NewClusterCaller unless a
+// pre-configured httpcli.Client must be reused.
+func ClusterEndpoints(endpoints ...string) Option {
+	return setEndpointList(newEndpointList(endpoints))
+}
+
+func setEndpointList(el *endpointList) Option {
+	return func(c *client) Option {
+		old := c.endpoints
+		c.endpoints = el
+		if el != nil {
+			// re-point the live client at the list's pinned endpoint so that PinnedEndpoint/
+			// Endpoints report, and the client actually talks to, the same endpoint.
+			c.With(httpcli.Endpoint(el.Pinned()))
+		}
+		return setEndpointList(old)
+	}
+}
+
+// endpointList tracks a rotating set of candidate master endpoints and the one currently pinned.
+// A discovered leader (e.g. from a Mesos redirect's Location header) is promoted to the front of
+// the list so that it becomes, and remains, the pinned endpoint until it too fails.
+type endpointList struct {
+	mu     sync.Mutex
+	all    []string
+	pinned int
+}
+
+func newEndpointList(endpoints []string) *endpointList {
+	all := append([]string(nil), endpoints...)
+	shuffle(all)
+	return &endpointList{all: all}
+}
+
+// shuffle randomizes the order of eps in place using a Fisher-Yates shuffle.
+func shuffle(eps []string) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := len(eps) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		eps[i], eps[j] = eps[j], eps[i]
+	}
+}
+
+// Pinned returns the endpoint that should currently be used to reach the cluster.
+func (el *endpointList) Pinned() string {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	return el.all[el.pinned]
+}
+
+// Endpoints returns a snapshot of the full candidate endpoint set, pinned endpoint first.
+func (el *endpointList) Endpoints() []string {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	result := make([]string, 0, len(el.all))
+	result = append(result, el.all[el.pinned])
+	for i, ep := range el.all {
+		if i != el.pinned {
+			result = append(result, ep)
+		}
+	}
+	return result
+}
+
+// next pins the next candidate endpoint (wrapping around) and returns it. It's invoked when the
+// currently pinned endpoint is believed to be unreachable or otherwise unusable.
+func (el *endpointList) next() string {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	el.pinned = (el.pinned + 1) % len(el.all)
+	return el.all[el.pinned]
+}
+
+// promote moves endpoint to the front of the candidate list and pins it, inserting it if it isn't
+// already a known candidate. This is how a discovered Mesos leader becomes, and stays, pinned.
+func (el *endpointList) promote(endpoint string) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	for i, ep := range el.all {
+		if ep == endpoint {
+			el.all[0], el.all[i] = el.all[i], el.all[0]
+			el.pinned = 0
+			return
+		}
+	}
+	el.all = append([]string{endpoint}, el.all...)
+	el.pinned = 0
+}
+
+// Endpoints returns the full set of candidate master endpoints known to the client, pinned
+// endpoint first. It returns nil if the client was not configured with ClusterEndpoints.
+func (cli *client) Endpoints() []string {
+	if cli.endpoints == nil {
+		return nil
+	}
+	return cli.endpoints.Endpoints()
+}
+
+// PinnedEndpoint returns the master endpoint the client currently believes is reachable, or the
+// empty string if the client was not configured with ClusterEndpoints.
+func (cli *client) PinnedEndpoint() string {
+	if cli.endpoints == nil {
+		return ""
+	}
+	return cli.endpoints.Pinned()
+}
+
+// isFailoverEligible reports whether err represents a transport-level failure of the pinned
+// endpoint -- connection refused, timeout, DNS failure, or an intermediary 5xx -- as opposed to a
+// Mesos-level error that a new endpoint wouldn't fix.
+func isFailoverEligible(err error) bool {
+	switch e := err.(type) {
+	case *mesosTransientError:
+		return true
+	case *url.Error:
+		return isFailoverEligible(e.Err)
+	case *net.DNSError:
+		return true
+	case *net.OpError:
+		return true
+	case net.Error:
+		return e.Timeout()
+	case httpcli.ProtocolError:
+		return false
+	}
+	return false
+}
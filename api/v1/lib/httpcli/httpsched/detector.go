@@ -0,0 +1,147 @@
+package httpsched
+
+import (
+	"context"
+
+	"github.com/mesos/mesos-go/api/v1/lib/httpcli"
+	"github.com/mesos/mesos-go/api/v1/lib/httpcli/httpsched/detector"
+)
+
+// WithLeaderDetector installs an out-of-band detector.Detector on the client: the first Call
+// blocks until an initial leader is observed, later LeaderEvents rewrite the endpoint ahead of
+// any Mesos 307, and apierrors.CodeNotLeader waits for the next event instead of parsing
+// Location.
+func WithLeaderDetector(d detector.Detector) Option {
+	return func(c *client) Option {
+		old := c.detector
+		c.detector = d
+		if d != nil {
+			c.startDetector(d)
+		} else {
+			c.Close() // stop whatever detector was previously running
+		}
+		return WithLeaderDetector(old)
+	}
+}
+
+// Closer is implemented by Callers that hold a background goroutine (e.g. a WithLeaderDetector
+// subscription) needing explicit teardown. Type-assert a Caller returned from NewCaller or
+// NewClusterCaller to Closer to release it.
+type Closer interface {
+	Close() error
+}
+
+// Close stops the client's leader detector, if one is configured. It's a no-op otherwise.
+func (cli *client) Close() error {
+	cli.detectorMu.Lock()
+	stop := cli.stopDetector
+	cli.stopDetector = nil
+	cli.detectorMu.Unlock()
+	if stop != nil {
+		stop()
+	}
+	return nil
+}
+
+// mesosAwaitLeaderError signals that the pinned endpoint reported it's no longer the leader and,
+// because a Detector is configured, the caller should wait for the detector's next LeaderEvent
+// rather than parse a Location header.
+type mesosAwaitLeaderError struct{}
+
+func (*mesosAwaitLeaderError) Error() string {
+	return "mesos server is no longer the leader; awaiting leader detector update"
+}
+
+// startDetector begins watching d for leadership changes. Any previously running detector is
+// stopped first. The goroutine it spawns never touches client state directly -- it only records
+// the latest LeaderEvent and wakes callers blocked in awaitLeader/awaitNextLeader; the endpoint
+// itself is only ever rewritten from applyPendingLeader, which runs on httpDo's serialized call
+// path, per the serial execution of Call documented on NewCaller.
+func (cli *client) startDetector(d detector.Detector) {
+	cli.Close()
+
+	cli.detectorMu.Lock()
+	cli.leaderCh = make(chan struct{})
+	cli.leaderReady = false
+	cli.pendingLeader = ""
+	cli.detectorMu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cli.detectorMu.Lock()
+	cli.stopDetector = cancel
+	cli.detectorMu.Unlock()
+
+	events, err := d.Detect(ctx)
+	if err != nil {
+		// don't wedge every future Call forever on a detector that failed to start
+		cli.recordLeader("")
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				cli.recordLeader(ev.URL)
+			}
+		}
+	}()
+}
+
+// recordLeader stashes the latest leader URL observed by the detector and wakes anything blocked
+// in awaitLeader/awaitNextLeader. It never touches cli.Endpoint()/cli.endpoints itself -- see
+// applyPendingLeader.
+func (cli *client) recordLeader(url string) {
+	cli.detectorMu.Lock()
+	defer cli.detectorMu.Unlock()
+	cli.pendingLeader = url
+	cli.leaderReady = true
+	close(cli.leaderCh)
+	cli.leaderCh = make(chan struct{})
+}
+
+// applyPendingLeader rewrites the client's endpoint to the most recently observed leader, if any
+// arrived since the last call. It must only be invoked from httpDo's serialized call path.
+func (cli *client) applyPendingLeader() {
+	cli.detectorMu.Lock()
+	url := cli.pendingLeader
+	cli.pendingLeader = ""
+	cli.detectorMu.Unlock()
+	if url == "" {
+		return
+	}
+	cli.With(httpcli.Endpoint(url))
+	if cli.endpoints != nil {
+		cli.endpoints.promote(url)
+	}
+}
+
+// awaitLeader blocks until the configured Detector has observed an initial leader, then applies
+// whatever leader is currently pending. It returns immediately if no Detector is configured.
+func (cli *client) awaitLeader() {
+	if cli.detector == nil {
+		return
+	}
+	cli.detectorMu.Lock()
+	ready := cli.leaderReady
+	ch := cli.leaderCh
+	cli.detectorMu.Unlock()
+	if !ready {
+		<-ch
+	}
+	cli.applyPendingLeader()
+}
+
+// awaitNextLeader blocks until the Detector reports a leadership change and applies it, used in
+// place of parsing a Location header when the current endpoint reports apierrors.CodeNotLeader.
+func (cli *client) awaitNextLeader() {
+	cli.detectorMu.Lock()
+	ch := cli.leaderCh
+	cli.detectorMu.Unlock()
+	<-ch
+	cli.applyPendingLeader()
+}
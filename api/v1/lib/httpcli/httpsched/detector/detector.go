@@ -0,0 +1,19 @@
+// Package detector defines a pluggable, out-of-band leader discovery mechanism for httpsched
+// clients -- a Zookeeper watch, a static file, a DNS SRV lookup, or any other framework-supplied
+// implementation -- for use in place of, or alongside, the Mesos 307 redirect.
+package detector
+
+import "context"
+
+// LeaderEvent carries the currently elected master's base URL, e.g. "http://10.0.0.1:5050".
+type LeaderEvent struct {
+	URL string
+}
+
+// Detector discovers Mesos master leadership changes out-of-band, i.e. without relying on the
+// Mesos 307 Not-Leader redirect. Detect starts watching for leadership changes and returns a
+// channel of LeaderEvents; the first event, once received, identifies the initial leader. The
+// channel is closed, and Detect's context cancelled, when detection should stop.
+type Detector interface {
+	Detect(ctx context.Context) (<-chan LeaderEvent, error)
+}
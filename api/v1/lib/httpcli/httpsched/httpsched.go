@@ -1,9 +1,10 @@
 package httpsched
 
 import (
-	"log"
+	"context"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/mesos/mesos-go/api/v1/lib"
@@ -11,6 +12,7 @@ import (
 	"github.com/mesos/mesos-go/api/v1/lib/encoding"
 	"github.com/mesos/mesos-go/api/v1/lib/httpcli"
 	"github.com/mesos/mesos-go/api/v1/lib/httpcli/apierrors"
+	"github.com/mesos/mesos-go/api/v1/lib/httpcli/httpsched/detector"
 	"github.com/mesos/mesos-go/api/v1/lib/scheduler"
 	"github.com/mesos/mesos-go/api/v1/lib/scheduler/calls"
 )
@@ -35,7 +37,20 @@ type (
 
 	client struct {
 		*httpcli.Client
-		redirect RedirectSettings
+		redirect      RedirectSettings
+		endpoints     *endpointList // non-nil when the client was configured w/ ClusterEndpoints
+		retry         RetryPolicy
+		checkRedirect func(from, to string, attempt int) error
+
+		detector      detector.Detector
+		stopDetector  context.CancelFunc
+		detectorMu    sync.Mutex
+		leaderCh      chan struct{} // closed & replaced each time the detector reports a new leader
+		leaderReady   bool          // true once an initial leader has been observed
+		pendingLeader string        // endpoint from the most recent LeaderEvent, applied by applyPendingLeader
+
+		requestID func() string // generates the X-Request-Id sent with every outbound Call
+		logger    Logger
 	}
 
 	// Caller is the public interface a framework scheduler's should consume
@@ -98,7 +113,12 @@ func MaxRedirects(mr int) Option {
 // of Call upon the returned caller are safely executed in a serial fashion. It is expected that
 // there are no other users of the given Client since its state may be modified by this impl.
 func NewCaller(cl *httpcli.Client, opts ...Option) calls.Caller {
-	result := &client{Client: cl, redirect: DefaultRedirectSettings}
+	result := &client{
+		Client:    cl,
+		redirect:  DefaultRedirectSettings,
+		requestID: defaultRequestID,
+		logger:    stdLogger{},
+	}
 	cl.With(result.redirectHandler())
 	for _, o := range opts {
 		if o != nil {
@@ -114,6 +134,14 @@ func NewCaller(cl *httpcli.Client, opts ...Option) calls.Caller {
 // httpDo decorates the inherited behavior w/ support for HTTP redirection to follow Mesos leadership changes.
 // NOTE: this implementation will change the state of the client upon Mesos leadership changes.
 func (cli *client) httpDo(m encoding.Marshaler, opt ...httpcli.RequestOpt) (resp mesos.Response, err error) {
+	cli.awaitLeader()
+	var reqID string
+	if cli.requestID != nil {
+		reqID = cli.requestID()
+		if reqID != "" {
+			opt = append(opt[:len(opt):len(opt)], httpcli.Header(HeaderRequestID, reqID))
+		}
+	}
 	var (
 		done            chan struct{} // avoid allocating these chans unless we actually need to redirect
 		redirectBackoff <-chan struct{}
@@ -124,25 +152,78 @@ func (cli *client) httpDo(m encoding.Marshaler, opt ...httpcli.RequestOpt) (resp
 			}
 			return redirectBackoff
 		}
+		retryDone       chan struct{}
+		retryBackoff    <-chan struct{}
+		getRetryBackoff = func() <-chan struct{} {
+			if retryBackoff == nil {
+				retryDone = make(chan struct{})
+				retryBackoff = backoff.Notifier(cli.retry.MinBackoff, cli.retry.MaxBackoff, retryDone)
+			}
+			return retryBackoff
+		}
+		retries = 0
+		chain   []RedirectRecord
 	)
 	defer func() {
 		if done != nil {
 			close(done)
 		}
+		if retryDone != nil {
+			close(retryDone)
+		}
 	}()
 	for attempt := 0; ; attempt++ {
 		resp, err = cli.Client.Do(m, opt...)
-		redirectErr, ok := err.(*mesosRedirectionError)
-		if !ok {
-			return resp, err
+		if _, ok := err.(*mesosAwaitLeaderError); ok {
+			if attempt >= cli.redirect.MaxAttempts {
+				return resp, err
+			}
+			cli.log().Infof("request=%s attempt=%d awaiting leader detector update", reqID, attempt)
+			cli.awaitNextLeader()
+			continue
 		}
-		if attempt < cli.redirect.MaxAttempts {
-			log.Println("redirecting to " + redirectErr.newURL)
+		if redirectErr, ok := err.(*mesosRedirectionError); ok {
+			if attempt >= cli.redirect.MaxAttempts {
+				return resp, err
+			}
+			from := cli.Endpoint()
+			if cli.checkRedirect != nil {
+				if cbErr := cli.checkRedirect(from, redirectErr.newURL, attempt); cbErr != nil {
+					return resp, cbErr
+				}
+			}
+			cli.log().Infof("request=%s attempt=%d redirecting from=%s to=%s", reqID, attempt, from, redirectErr.newURL)
+			chain = append(chain, RedirectRecord{From: from, To: redirectErr.newURL, Attempt: attempt})
+			if cli.endpoints != nil {
+				cli.endpoints.promote(redirectErr.newURL)
+			}
 			cli.With(httpcli.Endpoint(redirectErr.newURL))
 			<-getBackoff()
 			continue
 		}
-		return
+		// give RetryPolicy a chance to retry the same endpoint before rotating to the next
+		// candidate -- otherwise endpoint failover always wins and 5xx/timeout retry from
+		// WithRetry never gets to fire.
+		if err != nil && retries < cli.retry.MaxRetries && cli.retry.retryable()(resp, err) {
+			retries++
+			cli.log().Debugf("request=%s attempt=%d retrying after transient error: %v", reqID, attempt, err)
+			<-getRetryBackoff()
+			continue
+		}
+		if err != nil && cli.endpoints != nil && isFailoverEligible(err) {
+			if attempt >= cli.redirect.MaxAttempts {
+				return resp, err
+			}
+			next := cli.endpoints.next()
+			cli.log().Warnf("request=%s attempt=%d failing over from=%s to=%s", reqID, attempt, cli.Endpoint(), next)
+			cli.With(httpcli.Endpoint(next))
+			<-getBackoff()
+			continue
+		}
+		if err == nil && len(chain) > 0 {
+			resp = &responseWithRedirects{Response: resp, chain: chain}
+		}
+		return resp, err
 	}
 }
 
@@ -157,6 +238,24 @@ func (mre *mesosRedirectionError) Error() string {
 	return "mesos server sent redirect to: " + mre.newURL
 }
 
+// mesosTransientError wraps a 5xx response from the pinned master endpoint so that it's eligible
+// for RetryPolicy (see retry.go) and, when the client is cluster-aware, for endpoint failover
+// (see ClusterEndpoints) rather than being returned straight to the caller.
+type mesosTransientError struct {
+	cause  error
+	status int // HTTP status code of the 5xx response
+}
+
+func (mte *mesosTransientError) Error() string {
+	return "mesos server returned a transient error: " + mte.cause.Error()
+}
+
+// Unwrap exposes the underlying error so that errors.As/errors.Is still see through this
+// wrapping (e.g. to an *apierrors.Error) for callers that inspect a Call's returned error.
+func (mte *mesosTransientError) Unwrap() error {
+	return mte.cause
+}
+
 func isErrNotLeader(err error) bool {
 	if err == nil {
 		return false
@@ -172,9 +271,23 @@ func isErrNotLeader(err error) bool {
 func (cli *client) redirectHandler() httpcli.Opt {
 	return httpcli.HandleResponse(func(hres *http.Response, err error) (mesos.Response, error) {
 		resp, err := cli.HandleResponse(hres, err) // default response handler
+		if err != nil && (cli.retry.MaxRetries > 0 || cli.endpoints != nil) && hres != nil && hres.StatusCode >= 500 {
+			if resp != nil {
+				resp.Close()
+			}
+			return nil, &mesosTransientError{cause: err, status: hres.StatusCode}
+		}
 		if err == nil || !isErrNotLeader(err) {
 			return resp, err
 		}
+		if cli.detector != nil {
+			// an out-of-band detector is configured; wait for its next event rather than
+			// trusting the Location header, which may not be externally reachable.
+			if resp != nil {
+				resp.Close()
+			}
+			return nil, &mesosAwaitLeaderError{}
+		}
 		// TODO(jdef) for now, we're tightly coupled to the httpcli package's Response type
 		res, ok := resp.(*httpcli.Response)
 		if !ok {
@@ -183,7 +296,7 @@ func (cli *client) redirectHandler() httpcli.Opt {
 			}
 			return nil, errNotHTTPCli
 		}
-		log.Println("master changed?")
+		cli.log().Infof("master changed away from %s", cli.Endpoint())
 		location, ok := buildNewEndpoint(res.Header.Get("Location"), cli.Endpoint())
 		if !ok {
 			return nil, errBadLocation
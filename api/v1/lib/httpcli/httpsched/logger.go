@@ -0,0 +1,39 @@
+package httpsched
+
+import "log"
+
+// Logger is an injectable sink for the structured events the client emits while following
+// redirects, failing over, retrying, and waiting on a leader detector -- so that request ID,
+// attempt count, and endpoint transitions can be correlated with master-side logs during leader
+// churn instead of guessed at from a bare log.Println.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// WithLogger installs a Logger on the client; defaults to stdLogger, which writes through the
+// standard "log" package, when not configured.
+func WithLogger(l Logger) Option {
+	return func(c *client) Option {
+		old := c.logger
+		c.logger = l
+		return WithLogger(old)
+	}
+}
+
+// log returns cli.logger, falling back to stdLogger if the client was configured with
+// WithLogger(nil).
+func (cli *client) log() Logger {
+	if cli.logger == nil {
+		return stdLogger{}
+	}
+	return cli.logger
+}
+
+// stdLogger is the default Logger, writing through the standard "log" package.
+type stdLogger struct{}
+
+func (stdLogger) Debugf(format string, args ...interface{}) { log.Printf("DEBUG "+format, args...) }
+func (stdLogger) Infof(format string, args ...interface{})  { log.Printf("INFO "+format, args...) }
+func (stdLogger) Warnf(format string, args ...interface{})  { log.Printf("WARN "+format, args...) }
@@ -0,0 +1,53 @@
+package httpsched
+
+import (
+	"errors"
+
+	"github.com/mesos/mesos-go/api/v1/lib"
+)
+
+// ErrRedirectAborted is a sentinel a CheckRedirect callback may return to decline a leadership
+// redirect. httpDo returns the callback's error as is (and so it surfaces from Call); any other
+// non-nil error from the callback aborts the redirect loop the same way.
+var ErrRedirectAborted = errors.New("httpsched: redirect aborted by CheckRedirect callback")
+
+// RedirectRecord describes a single hop of the redirect chain followed while completing a Call;
+// it's recorded from to give frameworks a structured alternative to the scheduler's log output.
+type RedirectRecord struct {
+	From    string
+	To      string
+	Attempt int
+}
+
+// CheckRedirect installs a callback that's invoked, with the endpoint the client is redirecting
+// from, the endpoint in the Mesos Location header, and the zero-based number of the Call's attempt
+// that was redirected (retries and failovers count as attempts too), before the client applies
+// the redirect. This mirrors net/http.Client.CheckRedirect and lets a
+// framework audit leadership changes with structured data, validate that the new endpoint is
+// within an allow-list of expected master hosts, or abort the redirect loop entirely by
+// returning ErrRedirectAborted.
+func CheckRedirect(f func(from, to string, attempt int) error) Option {
+	return func(c *client) Option {
+		old := c.checkRedirect
+		c.checkRedirect = f
+		return CheckRedirect(old)
+	}
+}
+
+// responseWithRedirects decorates a mesos.Response with the chain of redirects that were
+// followed while completing the Call that produced it.
+type responseWithRedirects struct {
+	mesos.Response
+	chain []RedirectRecord
+}
+
+// RedirectChain returns the sequence of redirects followed to obtain this response, oldest first.
+func (r *responseWithRedirects) RedirectChain() []RedirectRecord {
+	return r.chain
+}
+
+// Unwrap returns the underlying mesos.Response, letting callers that need to type-assert a
+// concrete response type (e.g. *httpcli.Response) see through the redirect-chain decoration.
+func (r *responseWithRedirects) Unwrap() mesos.Response {
+	return r.Response
+}
@@ -0,0 +1,52 @@
+package httpsched
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/mesos/mesos-go/api/v1/lib"
+	"github.com/mesos/mesos-go/api/v1/lib/httpcli"
+)
+
+// HeaderRequestID is the header used to propagate a per-Call request ID to and from the Mesos
+// master, allowing scheduler-side log lines to be correlated with master-side ones.
+const HeaderRequestID = "X-Request-Id"
+
+// WithRequestIDFunc installs a func that generates a request ID for every outbound Call; it's
+// sent to the master as the HeaderRequestID header. Defaults to a random 16-byte hex string, in
+// the style of Arvados' client, when not configured.
+func WithRequestIDFunc(f func() string) Option {
+	return func(c *client) Option {
+		old := c.requestID
+		c.requestID = f
+		return WithRequestIDFunc(old)
+	}
+}
+
+// defaultRequestID generates a random 16-byte hex request ID.
+func defaultRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// RequestIDFromResponse returns the HeaderRequestID value the master echoed back on resp, or ""
+// if resp didn't carry one. resp is unwrapped (e.g. past the redirect-chain decoration httpDo
+// applies to a Call that followed at least one redirect) before the lookup.
+func RequestIDFromResponse(resp mesos.Response) string {
+	for {
+		if res, ok := resp.(*httpcli.Response); ok {
+			if res == nil {
+				return ""
+			}
+			return res.Header.Get(HeaderRequestID)
+		}
+		u, ok := resp.(interface{ Unwrap() mesos.Response })
+		if !ok {
+			return ""
+		}
+		resp = u.Unwrap()
+	}
+}
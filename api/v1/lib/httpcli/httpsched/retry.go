@@ -0,0 +1,62 @@
+package httpsched
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/mesos/mesos-go/api/v1/lib"
+)
+
+// RetryPolicy configures how a client retries the initial request/response handshake of a Call
+// in the face of transient transport failures (connection reset, EOF mid-stream, a 502/503/504
+// from an intermediary, etc.), modeled on hashicorp/go-retryablehttp. It's independent of, and
+// composes with, the leadership-redirect loop: a single Call may traverse both.
+type RetryPolicy struct {
+	MaxRetries int           // max number of retries of the initial handshake; 0 disables retry
+	MinBackoff time.Duration // should be less than MaxBackoff
+	MaxBackoff time.Duration // should be more than MinBackoff
+
+	// Retryable reports whether a failed attempt (resp, err as returned by httpDo) should be
+	// retried. Defaults to DefaultRetryable when nil.
+	Retryable func(resp mesos.Response, err error) bool
+}
+
+// DefaultRetryPolicy disables retry; frameworks opt in via WithRetry.
+var DefaultRetryPolicy = RetryPolicy{}
+
+// WithRetry installs a RetryPolicy on the client; it's consulted by httpDo on every failed attempt
+// that isn't a leadership redirect, before endpoint failover is considered.
+func WithRetry(rp RetryPolicy) Option {
+	return func(c *client) Option {
+		old := c.retry
+		c.retry = rp
+		return WithRetry(old)
+	}
+}
+
+func (rp *RetryPolicy) retryable() func(mesos.Response, error) bool {
+	if rp.Retryable != nil {
+		return rp.Retryable
+	}
+	return DefaultRetryable
+}
+
+// DefaultRetryable retries on network timeouts/temporary errors, an unexpected EOF reading the
+// response body, and 502/503/504 status codes -- the same class of failure go-retryablehttp
+// retries by default.
+func DefaultRetryable(resp mesos.Response, err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return false
+	case *mesosTransientError:
+		switch e.status {
+		case 502, 503, 504:
+			return true
+		}
+		return false
+	case net.Error:
+		return e.Temporary() || e.Timeout()
+	}
+	return err == io.ErrUnexpectedEOF || err == io.ErrClosedPipe
+}